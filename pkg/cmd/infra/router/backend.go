@@ -0,0 +1,192 @@
+package router
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
+
+	"github.com/openshift/origin/pkg/cmd/util/clientcmd"
+	"github.com/openshift/origin/pkg/router/backend"
+	"github.com/openshift/origin/pkg/router/envoy"
+	"github.com/openshift/origin/pkg/router/traefik"
+	"github.com/openshift/origin/pkg/version"
+)
+
+const (
+	backendTemplateHAProxy = "template-haproxy"
+	backendTraefik         = "traefik"
+	backendEnvoyXDS        = "envoy-xds"
+)
+
+// backendOptions is implemented by each backend's option group so NewCommandRouter can drive
+// them uniformly from the same cobra command tree.
+type backendOptions interface {
+	Bind(flag *pflag.FlagSet)
+	Complete() error
+	Validate() error
+	Run() error
+}
+
+// NewCommandRouter provides the CLI handler for every router backend. The concrete backend is
+// selected with --backend (template-haproxy by default) and only that backend's flags are
+// bound, since the option groups otherwise overlap in flag names (e.g. --working-dir).
+func NewCommandRouter(name string) *cobra.Command {
+	backendName := parseBackendFlag(os.Args[1:])
+
+	options, err := newBackendOptions(backendName)
+	if err != nil {
+		// Defer the error to Run so cobra's usual -h/usage handling still works; Complete()
+		// returns that same error.
+		options = &unknownBackendOptions{name: backendName, err: err}
+	}
+
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s%s", name, clientcmd.ConfigSyntax),
+		Short: "Start a router",
+		Long:  routerLong,
+		Run: func(c *cobra.Command, args []string) {
+			if o, ok := options.(interface{ SetNamespace(string) }); ok {
+				o.SetNamespace(cmdutil.GetFlagString(c, "namespace"))
+			}
+			cmdutil.CheckErr(options.Complete())
+			cmdutil.CheckErr(options.Validate())
+			cmdutil.CheckErr(options.Run())
+		},
+	}
+
+	cmd.AddCommand(version.NewVersionCommand(name, false))
+
+	flag := cmd.Flags()
+	flag.String("backend", backendTemplateHAProxy, "The router backend to run: template-haproxy, traefik, or envoy-xds")
+	options.Bind(flag)
+
+	return cmd
+}
+
+// parseBackendFlag does a throwaway parse of argv looking only for --backend, since which
+// flags the real command exposes depends on its value.
+func parseBackendFlag(args []string) string {
+	flag := pflag.NewFlagSet("router-backend-probe", pflag.ContinueOnError)
+	flag.ParseErrorsWhitelist.UnknownFlags = true
+	flag.Usage = func() {}
+	backendName := flag.String("backend", backendTemplateHAProxy, "")
+	_ = flag.Parse(args)
+	return *backendName
+}
+
+func newBackendOptions(name string) (backendOptions, error) {
+	switch name {
+	case backendTemplateHAProxy:
+		options := &TemplateRouterOptions{Config: clientcmd.NewConfig()}
+		options.Config.FromFile = true
+		return &templateRouterBackendOptions{TemplateRouterOptions: options}, nil
+	case backendTraefik:
+		return &TraefikRouterOptions{Config: clientcmd.NewConfig()}, nil
+	case backendEnvoyXDS:
+		return &EnvoyRouterOptions{Config: clientcmd.NewConfig()}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized --backend %q: must be %s, %s, or %s", name, backendTemplateHAProxy, backendTraefik, backendEnvoyXDS)
+	}
+}
+
+// templateRouterBackendOptions adapts the pre-existing *TemplateRouterOptions (whose Run method
+// predates the Backend interface) onto the same backendOptions contract the other backends use.
+type templateRouterBackendOptions struct {
+	*TemplateRouterOptions
+}
+
+func (o *templateRouterBackendOptions) SetNamespace(ns string) { o.RouterSelection.Namespace = ns }
+
+// unknownBackendOptions defers an unrecognized --backend value to Complete(), rather than
+// failing while cobra is still constructing the command (which would also break -h/--help).
+type unknownBackendOptions struct {
+	name string
+	err  error
+}
+
+func (o *unknownBackendOptions) Bind(flag *pflag.FlagSet) {}
+func (o *unknownBackendOptions) Complete() error          { return o.err }
+func (o *unknownBackendOptions) Validate() error          { return o.err }
+func (o *unknownBackendOptions) Run() error               { return o.err }
+
+var _ backend.Backend = (*traefik.Backend)(nil)
+var _ backend.Backend = (*envoy.Backend)(nil)
+
+// backendPlugin adapts a backend.Backend onto controller.Plugin so backends that don't predate
+// the Backend interface (unlike the template-haproxy backend) can be driven by the same
+// controller factory. It accumulates admitted routes and endpoints in memory and hands the
+// current snapshot to the backend on every Commit, the same way TemplatePlugin accumulates its
+// own internal state.
+//
+// NOTE: nothing currently calls AddRoute/RemoveRoute/AddEndpoints/RemoveEndpoints.
+// controller.Controller.Run only logs today; it does not yet subscribe to route/endpoint
+// informers and drive Plugin (this is true of template-haproxy's HandleRoute/HandleEndpoints
+// too). Until that controller event-loop work lands, --backend=traefik and --backend=envoy-xds
+// start up and serve, but never receive a route or endpoint, so they never emit real
+// configuration: they are not yet a functional substitute for --backend=template-haproxy.
+type backendPlugin struct {
+	backend backend.Backend
+
+	mu        sync.Mutex
+	routes    map[string]backend.RouteState
+	endpoints map[string]backend.EndpointState
+}
+
+func newBackendPlugin(b backend.Backend) *backendPlugin {
+	return &backendPlugin{
+		backend:   b,
+		routes:    map[string]backend.RouteState{},
+		endpoints: map[string]backend.EndpointState{},
+	}
+}
+
+// AddRoute records route as admitted so the next Commit includes it in the backend's state.
+func (p *backendPlugin) AddRoute(route backend.RouteState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.routes[route.Namespace+"/"+route.Name] = route
+}
+
+// RemoveRoute forgets a previously admitted route.
+func (p *backendPlugin) RemoveRoute(namespace, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.routes, namespace+"/"+name)
+}
+
+// AddEndpoints records endpoints backing a service so the next Commit includes them in the
+// backend's state.
+func (p *backendPlugin) AddEndpoints(endpoints backend.EndpointState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endpoints[endpoints.Namespace+"/"+endpoints.Service] = endpoints
+}
+
+// RemoveEndpoints forgets previously recorded endpoints for a service.
+func (p *backendPlugin) RemoveEndpoints(namespace, service string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.endpoints, namespace+"/"+service)
+}
+
+func (p *backendPlugin) Commit() error {
+	p.mu.Lock()
+	state := backend.RouterState{
+		Routes:    make([]backend.RouteState, 0, len(p.routes)),
+		Endpoints: make([]backend.EndpointState, 0, len(p.endpoints)),
+	}
+	for _, r := range p.routes {
+		state.Routes = append(state.Routes, r)
+	}
+	for _, e := range p.endpoints {
+		state.Endpoints = append(state.Endpoints, e)
+	}
+	p.mu.Unlock()
+
+	return p.backend.Commit(state)
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"io"
+	"testing"
+
+	"github.com/openshift/origin/pkg/router/backend"
+)
+
+type fakeBackend struct {
+	committed backend.RouterState
+}
+
+func (b *fakeBackend) Configure(backend.BackendConfig) error { return nil }
+func (b *fakeBackend) Commit(state backend.RouterState) error {
+	b.committed = state
+	return nil
+}
+func (b *fakeBackend) Stats() (io.Reader, error) { return nil, nil }
+
+func TestBackendPluginAccumulatesState(t *testing.T) {
+	fake := &fakeBackend{}
+	p := newBackendPlugin(fake)
+
+	p.AddRoute(backend.RouteState{Namespace: "ns", Name: "r1", Host: "r1.example.com"})
+	p.AddEndpoints(backend.EndpointState{Namespace: "ns", Service: "svc1", Addresses: []string{"10.0.0.1"}, Port: 8080})
+
+	if err := p.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.committed.Routes) != 1 || len(fake.committed.Endpoints) != 1 {
+		t.Fatalf("expected the accumulated route and endpoints to be committed, got %+v", fake.committed)
+	}
+
+	p.RemoveRoute("ns", "r1")
+	p.RemoveEndpoints("ns", "svc1")
+
+	if err := p.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.committed.Routes) != 0 || len(fake.committed.Endpoints) != 0 {
+		t.Fatalf("expected removed route and endpoints to be absent from the next commit, got %+v", fake.committed)
+	}
+}
+
+func TestParseBackendFlag(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default", []string{"--template", "/tmp/t"}, backendTemplateHAProxy},
+		{"explicit long", []string{"--backend", "envoy-xds"}, backendEnvoyXDS},
+		{"explicit equals", []string{"--backend=traefik"}, backendTraefik},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseBackendFlag(tt.args); got != tt.want {
+				t.Errorf("parseBackendFlag(%v) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"errors"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/origin/pkg/cmd/util"
+	"github.com/openshift/origin/pkg/cmd/util/clientcmd"
+	"github.com/openshift/origin/pkg/router/backend"
+	"github.com/openshift/origin/pkg/router/envoy"
+)
+
+// EnvoyRouterOptions configures the --backend=envoy-xds router, which serves RDS/CDS/EDS/LDS
+// over gRPC so an Envoy sidecar can consume routes directly with no file writes.
+type EnvoyRouterOptions struct {
+	Config *clientcmd.Config
+
+	ListenAddr     string
+	NodeID         string
+	HTTPListenPort int
+
+	RouterSelection
+	RouterLogging
+}
+
+// Bind registers the flags specific to the Envoy xDS backend.
+func (o *EnvoyRouterOptions) Bind(flag *pflag.FlagSet) {
+	flag.StringVar(&o.ListenAddr, "xds-listen-addr", util.Env("ROUTER_XDS_LISTEN_ADDR", "127.0.0.1:18000"), "The address the xDS gRPC server listens on")
+	flag.StringVar(&o.NodeID, "xds-node-id", util.Env("ROUTER_XDS_NODE_ID", ""), "The Envoy node ID this router instance serves configuration for")
+	flag.IntVar(&o.HTTPListenPort, "envoy-http-listen-port", 8080, "The port the LDS listener advertises for downstream HTTP traffic; must match a listener or bind in the sidecar's bootstrap config")
+	o.RouterSelection.Bind(flag)
+	o.RouterLogging.Bind(flag)
+}
+
+// SetNamespace restricts this router to a single namespace.
+func (o *EnvoyRouterOptions) SetNamespace(ns string) { o.RouterSelection.Namespace = ns }
+
+// Complete fills in derived fields after flags have been parsed.
+func (o *EnvoyRouterOptions) Complete() error {
+	return o.RouterSelection.Complete()
+}
+
+// Validate checks that the options are internally consistent.
+func (o *EnvoyRouterOptions) Validate() error {
+	if len(o.NodeID) == 0 {
+		return errors.New("--xds-node-id must be set so Envoy's bootstrap config can be matched to this router's snapshot")
+	}
+	return nil
+}
+
+// Run launches the Envoy xDS backend. It never exits.
+func (o *EnvoyRouterOptions) Run() error {
+	log, err := o.RouterLogging.Logger()
+	if err != nil {
+		return err
+	}
+	log = log.WithName("router").WithValues("backend", backendEnvoyXDS)
+
+	b := &envoy.Backend{ListenAddr: o.ListenAddr, NodeID: o.NodeID, HTTPListenPort: o.HTTPListenPort}
+	if err := b.Configure(backend.BackendConfig{}); err != nil {
+		return err
+	}
+
+	oc, kc, err := o.Config.Clients()
+	if err != nil {
+		return err
+	}
+
+	factory := o.RouterSelection.NewFactory(oc, kc, log)
+	routeController := factory.Create(newBackendPlugin(b))
+	routeController.Run()
+
+	select {}
+}
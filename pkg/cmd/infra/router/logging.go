@@ -0,0 +1,82 @@
+package router
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/openshift/origin/pkg/cmd/util"
+)
+
+// Sampling bounds repeated high-volume entries (e.g. one line per connection) to the first
+// samplingFirst occurrences per samplingTick, then one in every samplingThereafter after that.
+const (
+	samplingTick       = time.Second
+	samplingFirst      = 100
+	samplingThereafter = 100
+)
+
+// RouterLogging controls the format, verbosity, and sampling of the structured log emitted by
+// the router. It replaces the ad-hoc use of glog across the router backends.
+type RouterLogging struct {
+	LogFormat   string
+	LogLevel    string
+	LogSampling bool
+}
+
+// Bind registers the logging flags shared by all router backends.
+func (o *RouterLogging) Bind(flag *pflag.FlagSet) {
+	flag.StringVar(&o.LogFormat, "log-format", util.Env("ROUTER_LOG_FORMAT", "text"), "The log encoding to use: text or json")
+	flag.StringVar(&o.LogLevel, "log-level", util.Env("ROUTER_LOG_LEVEL", "info"), "The minimum log level to emit: info, debug, or trace")
+	flag.BoolVar(&o.LogSampling, "log-sampling", util.EnvBool("ROUTER_LOG_SAMPLING", true), "If true, repeated high-volume log entries (such as per-connection events) are sampled instead of emitted in full")
+}
+
+// zapLevel maps the router's named log levels onto zap levels. "trace" has no direct zap
+// equivalent and is mapped to the next verbosity level down (-2).
+func zapLevel(name string) (zapcore.Level, error) {
+	switch name {
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "debug":
+		return zapcore.Level(-1), nil
+	case "trace":
+		return zapcore.Level(-2), nil
+	default:
+		return 0, fmt.Errorf("unrecognized --log-level %q: must be info, debug, or trace", name)
+	}
+}
+
+// Logger builds the logr.Logger used for the lifetime of the router process.
+func (o *RouterLogging) Logger() (logr.Logger, error) {
+	level, err := zapLevel(o.LogLevel)
+	if err != nil {
+		return logr.Logger{}, err
+	}
+
+	var encoder zapcore.Encoder
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "timestamp"
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	switch o.LogFormat {
+	case "json":
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	case "text":
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	default:
+		return logr.Logger{}, fmt.Errorf("unrecognized --log-format %q: must be text or json", o.LogFormat)
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(os.Stderr)), level)
+	if o.LogSampling {
+		core = zapcore.NewSamplerWithOptions(core, samplingTick, samplingFirst, samplingThereafter)
+	}
+
+	return zapr.NewLogger(zap.New(core)), nil
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/origin/pkg/cmd/util"
+	"github.com/openshift/origin/pkg/router/controller"
+)
+
+// RouterSelection controls which routes and endpoints a router instance admits.
+type RouterSelection struct {
+	Namespace       string
+	NamespaceLabels string
+	ProjectLabels   string
+	Labels          string
+	Fields          string
+
+	IncludeUDP bool
+}
+
+// Bind registers selection flags shared by all router backends.
+func (o *RouterSelection) Bind(flag *pflag.FlagSet) {
+	flag.StringVar(&o.NamespaceLabels, "namespace-labels", util.Env("NAMESPACE_LABELS", ""), "A label selector to apply to namespaces to watch, if empty all are watched")
+	flag.StringVar(&o.ProjectLabels, "project-labels", util.Env("PROJECT_LABELS", ""), "A label selector to apply to projects to watch, if empty all are watched")
+	flag.StringVar(&o.Labels, "labels", util.Env("ROUTE_LABELS", ""), "A label selector to apply to routes to watch")
+	flag.StringVar(&o.Fields, "fields", util.Env("ROUTE_FIELDS", ""), "A field selector to apply to routes to watch")
+	flag.BoolVar(&o.IncludeUDP, "include-udp-endpoints", false, "If true, UDP endpoints will be considered as candidates for routing")
+}
+
+// Complete fills in any derived fields after flags have been parsed.
+func (o *RouterSelection) Complete() error {
+	return nil
+}
+
+// RouteSelectionFunc returns a function used to decide whether a given route should be
+// admitted by this router instance.
+func (o *RouterSelection) RouteSelectionFunc() func(namespace, name string) bool {
+	return func(namespace, name string) bool {
+		return true
+	}
+}
+
+// NewFactory builds a controller.Factory whose controllers log through log. The namespaces,
+// labels, and fields configured on this RouterSelection, and the oc and kc clients, are not
+// used by the factory.
+func (o *RouterSelection) NewFactory(oc, kc interface{}, log logr.Logger) *controller.Factory {
+	return controller.NewFactory(log)
+}
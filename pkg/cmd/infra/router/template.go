@@ -3,22 +3,28 @@ package router
 import (
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
 	"github.com/golang/glog"
-	"github.com/spf13/cobra"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/pflag"
 
-	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
 	ktypes "k8s.io/kubernetes/pkg/types"
 
 	"github.com/openshift/origin/pkg/cmd/util"
 	"github.com/openshift/origin/pkg/cmd/util/clientcmd"
 	"github.com/openshift/origin/pkg/router/controller"
+	"github.com/openshift/origin/pkg/router/metrics"
 	templateplugin "github.com/openshift/origin/pkg/router/template"
 	"github.com/openshift/origin/pkg/util/proc"
-	"github.com/openshift/origin/pkg/version"
 )
 
 const (
@@ -42,6 +48,8 @@ type TemplateRouterOptions struct {
 	TemplateRouter
 	RouterStats
 	RouterSelection
+	RouterLogging
+	RouteLimitDefaults
 }
 
 type TemplateRouter struct {
@@ -53,6 +61,9 @@ type TemplateRouter struct {
 	DefaultCertificate     string
 	DefaultCertificatePath string
 	RouterService          *ktypes.NamespacedName
+
+	RuntimeSocket string
+	CertsDir      string
 }
 
 func (o *TemplateRouter) Bind(flag *pflag.FlagSet) {
@@ -62,6 +73,8 @@ func (o *TemplateRouter) Bind(flag *pflag.FlagSet) {
 	flag.StringVar(&o.DefaultCertificatePath, "default-certificate-path", util.Env("DEFAULT_CERTIFICATE_PATH", ""), "A path to default certificate to use for routes that don't expose a TLS server cert; in PEM format")
 	flag.StringVar(&o.TemplateFile, "template", util.Env("TEMPLATE_FILE", ""), "The path to the template file to use")
 	flag.StringVar(&o.ReloadScript, "reload", util.Env("RELOAD_SCRIPT", ""), "The path to the reload script to use")
+	flag.StringVar(&o.RuntimeSocket, "runtime-socket", util.Env("ROUTER_HAPROXY_SOCKET", ""), "The path to the HAProxy stats/admin UNIX socket used to push endpoint-only changes without a reload")
+	flag.StringVar(&o.CertsDir, "certs-dir", util.Env("ROUTER_CERTS_DIR", ""), "A directory of additional TLS certificates to watch; changes to its contents, to --template, or to --default-certificate-path trigger a hot reload without restarting the router")
 
 	interval := util.Env("RELOAD_INTERVAL", "0s")
 
@@ -74,48 +87,52 @@ func (o *TemplateRouter) Bind(flag *pflag.FlagSet) {
 	flag.DurationVar(&o.ReloadInterval, "interval", o.ReloadInterval, "Controls how often router reloads are invoked. Mutiple router reload requests are coalesced for the duration of this interval since the last reload time.")
 }
 
+// RouteLimitDefaults sets the cluster-wide floors for per-route rate limiting, retries, and
+// connect timeouts applied when a route omits the corresponding annotation.
+type RouteLimitDefaults struct {
+	DefaultRateLimitHTTP  int
+	DefaultRetries        int
+	DefaultConnectTimeout time.Duration
+}
+
+func (o *RouteLimitDefaults) Bind(flag *pflag.FlagSet) {
+	flag.IntVar(&o.DefaultRateLimitHTTP, "default-rate-limit-http", 0, "The default maximum HTTP requests/sec a single source IP may sustain on a route that does not set the rate-limit-connections.rate-http annotation; 0 disables the floor")
+	flag.IntVar(&o.DefaultRetries, "default-retries", 0, "The default number of times HAProxy retries a failed request against another backend server when a route does not set the retries annotation")
+	flag.DurationVar(&o.DefaultConnectTimeout, "default-connect-timeout", 5*time.Second, "The default timeout HAProxy waits to establish a backend connection when a route does not set the timeout-backend annotation")
+}
+
 type RouterStats struct {
 	StatsPortString string
 	StatsPassword   string
 	StatsUsername   string
 
 	StatsPort int
+
+	MetricsListenAddr  string
+	MetricsTLSCert     string
+	MetricsTLSKey      string
+	MetricsBearerToken string
 }
 
 func (o *RouterStats) Bind(flag *pflag.FlagSet) {
 	flag.StringVar(&o.StatsPortString, "stats-port", util.Env("STATS_PORT", ""), "If the underlying router implementation can provide statistics this is a hint to expose it on this port.")
 	flag.StringVar(&o.StatsPassword, "stats-password", util.Env("STATS_PASSWORD", ""), "If the underlying router implementation can provide statistics this is the requested password for auth.")
 	flag.StringVar(&o.StatsUsername, "stats-user", util.Env("STATS_USERNAME", ""), "If the underlying router implementation can provide statistics this is the requested username for auth.")
+	flag.StringVar(&o.MetricsListenAddr, "metrics-listen-addr", util.Env("ROUTER_METRICS_LISTEN_ADDR", ""), "If set, expose Prometheus metrics on this address at /metrics: reload counts and durations, and route admission errors. HAProxy stats are not scraped into them")
+	flag.StringVar(&o.MetricsTLSCert, "metrics-tls-cert", util.Env("ROUTER_METRICS_TLS_CERT", ""), "The path to a TLS certificate to serve /metrics over HTTPS; requires --metrics-tls-key")
+	flag.StringVar(&o.MetricsTLSKey, "metrics-tls-key", util.Env("ROUTER_METRICS_TLS_KEY", ""), "The path to the TLS private key matching --metrics-tls-cert")
+	flag.StringVar(&o.MetricsBearerToken, "metrics-bearer-token", util.Env("ROUTER_METRICS_BEARER_TOKEN", ""), "If set, require this bearer token on every /metrics request; compatible with the Prometheus operator's ServiceMonitor bearerToken auth")
 }
 
-// NewCommndTemplateRouter provides CLI handler for the template router backend
-func NewCommandTemplateRouter(name string) *cobra.Command {
-	options := &TemplateRouterOptions{
-		Config: clientcmd.NewConfig(),
-	}
-	options.Config.FromFile = true
-
-	cmd := &cobra.Command{
-		Use:   fmt.Sprintf("%s%s", name, clientcmd.ConfigSyntax),
-		Short: "Start a router",
-		Long:  routerLong,
-		Run: func(c *cobra.Command, args []string) {
-			options.RouterSelection.Namespace = cmdutil.GetFlagString(c, "namespace")
-			cmdutil.CheckErr(options.Complete())
-			cmdutil.CheckErr(options.Validate())
-			cmdutil.CheckErr(options.Run())
-		},
-	}
-
-	cmd.AddCommand(version.NewVersionCommand(name, false))
-
-	flag := cmd.Flags()
-	options.Config.Bind(flag)
-	options.TemplateRouter.Bind(flag)
-	options.RouterStats.Bind(flag)
-	options.RouterSelection.Bind(flag)
-
-	return cmd
+// Bind registers the flags specific to the template-haproxy backend. It is invoked from
+// NewCommandRouter once --backend has resolved to template-haproxy.
+func (o *TemplateRouterOptions) Bind(flag *pflag.FlagSet) {
+	o.Config.Bind(flag)
+	o.TemplateRouter.Bind(flag)
+	o.RouterStats.Bind(flag)
+	o.RouterSelection.Bind(flag)
+	o.RouterLogging.Bind(flag)
+	o.RouteLimitDefaults.Bind(flag)
 }
 
 func (o *TemplateRouterOptions) Complete() error {
@@ -157,12 +174,25 @@ func (o *TemplateRouterOptions) Validate() error {
 	if len(o.ReloadScript) == 0 {
 		return errors.New("reload script must be specified")
 	}
+
+	if o.DefaultRateLimitHTTP < 0 {
+		return fmt.Errorf("--default-rate-limit-http must not be negative: %d", o.DefaultRateLimitHTTP)
+	}
+	if o.DefaultRetries < 0 {
+		return fmt.Errorf("--default-retries must not be negative: %d", o.DefaultRetries)
+	}
+	if o.DefaultConnectTimeout <= 0 {
+		return fmt.Errorf("--default-connect-timeout must be a positive duration: %s", o.DefaultConnectTimeout)
+	}
 	return nil
 }
 
-// Run launches a template router using the provided options. It never exits.
-func (o *TemplateRouterOptions) Run() error {
-	pluginCfg := templateplugin.TemplatePluginConfig{
+// pluginConfig builds a templateplugin.TemplatePluginConfig from the current option values, log,
+// and (if configured) metrics sink. Run calls this once at startup, and watchForChanges calls it
+// again on every SIGHUP or watched file change, so a reconfigure always picks up the same fields
+// Run did.
+func (o *TemplateRouterOptions) pluginConfig(log logr.Logger, m *metrics.Metrics) templateplugin.TemplatePluginConfig {
+	return templateplugin.TemplatePluginConfig{
 		WorkingDir:             o.WorkingDir,
 		TemplatePath:           o.TemplateFile,
 		ReloadScriptPath:       o.ReloadScript,
@@ -174,8 +204,45 @@ func (o *TemplateRouterOptions) Run() error {
 		StatsPassword:          o.StatsPassword,
 		PeerService:            o.RouterService,
 		IncludeUDP:             o.RouterSelection.IncludeUDP,
+		Logger:                 log,
+		RuntimeSocket:          o.RuntimeSocket,
+		RouteLimitDefaults: templateplugin.RouteLimitDefaults{
+			RateLimitHTTPRate: o.DefaultRateLimitHTTP,
+			Retries:           o.DefaultRetries,
+			ConnectTimeout:    o.DefaultConnectTimeout,
+		},
+		Metrics: m,
+	}
+}
+
+// Run launches a template router using the provided options. It never exits.
+func (o *TemplateRouterOptions) Run() error {
+	log, err := o.RouterLogging.Logger()
+	if err != nil {
+		return err
+	}
+	log = log.WithName("router").WithValues("name", o.RouterName)
+
+	var m *metrics.Metrics
+	if len(o.MetricsListenAddr) > 0 {
+		registry := prometheus.NewRegistry()
+		m = metrics.NewMetrics(registry)
+
+		metricsServer := metrics.NewServer(metrics.ServerConfig{
+			ListenAddr:  o.MetricsListenAddr,
+			TLSCert:     o.MetricsTLSCert,
+			TLSKey:      o.MetricsTLSKey,
+			BearerToken: o.MetricsBearerToken,
+		}, registry)
+		if err := metricsServer.Start(func(err error) {
+			log.Error(err, "metrics server stopped unexpectedly")
+		}); err != nil {
+			return err
+		}
 	}
 
+	pluginCfg := o.pluginConfig(log, m)
+
 	templatePlugin, err := templateplugin.NewTemplatePlugin(pluginCfg)
 	if err != nil {
 		return err
@@ -186,14 +253,96 @@ func (o *TemplateRouterOptions) Run() error {
 		return err
 	}
 
-	statusPlugin := controller.NewStatusAdmitter(templatePlugin, oc, o.RouterName)
+	statusPlugin := controller.NewStatusAdmitter(templatePlugin, o.RouterName, log)
+	if m != nil {
+		statusPlugin.AdmissionErrors = m.RouteAdmissionErrors
+	}
 	plugin := controller.NewUniqueHost(statusPlugin, o.RouteSelectionFunc(), statusPlugin)
 
-	factory := o.RouterSelection.NewFactory(oc, kc)
-	controller := factory.Create(plugin)
-	controller.Run()
+	factory := o.RouterSelection.NewFactory(oc, kc, log)
+	routeController := factory.Create(plugin)
+	routeController.Run()
 
 	proc.StartReaper()
 
-	select {}
+	o.watchForChanges(log, m, templatePlugin)
+	return nil
+}
+
+// watchForChanges blocks, triggering a Reconfigure and forced Commit on templatePlugin whenever
+// the process receives SIGHUP or one of --template, --default-certificate-path, or --certs-dir
+// changes on disk. This lets an operator roll a new certificate or template into place without
+// restarting the router and dropping its in-flight connections. It never returns.
+func (o *TemplateRouterOptions) watchForChanges(log logr.Logger, m *metrics.Metrics, templatePlugin *templateplugin.TemplatePlugin) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error(err, "unable to watch for certificate and template changes, hot reload on file change is disabled; SIGHUP will still trigger a reload")
+		for range hup {
+			o.reload(log, m, templatePlugin, "SIGHUP")
+		}
+		return
+	}
+	defer watcher.Close()
+
+	// o.TemplateFile and o.DefaultCertificatePath are leaf files, most often Kubernetes
+	// Secret/ConfigMap mounts. Those rotate by atomically swapping a symlink in their containing
+	// directory rather than writing the leaf file in place, which silently breaks an inotify
+	// watch added directly on the leaf: the watch follows the old inode, not the name, and the
+	// rotation event itself fires on the directory (the "..data" symlink rename), never on the
+	// leaf filename. So watch the containing directory, and treat any event inside it as
+	// relevant rather than requiring an exact match on the leaf path. o.CertsDir is already a
+	// directory, so it's watched (and matched) the same way.
+	dirs := map[string]bool{}
+	for _, path := range []string{o.TemplateFile, o.DefaultCertificatePath} {
+		if len(path) == 0 {
+			continue
+		}
+		dirs[filepath.Dir(path)] = true
+	}
+	if len(o.CertsDir) > 0 {
+		dirs[o.CertsDir] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			log.Error(err, "unable to watch directory for changes", "directory", dir)
+		}
+	}
+
+	for {
+		select {
+		case <-hup:
+			o.reload(log, m, templatePlugin, "SIGHUP")
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			inCertsDir := len(o.CertsDir) > 0 && strings.HasPrefix(event.Name, o.CertsDir+string(filepath.Separator))
+			if !dirs[filepath.Dir(event.Name)] && !inCertsDir {
+				continue
+			}
+			o.reload(log, m, templatePlugin, event.Name)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error(err, "error watching for certificate and template changes")
+		}
+	}
+}
+
+// reload rebuilds the plugin config from the current option values and pushes it through
+// Reconfigure, then forces an immediate Commit so the change takes effect without waiting for
+// the next route or endpoint event.
+func (o *TemplateRouterOptions) reload(log logr.Logger, m *metrics.Metrics, templatePlugin *templateplugin.TemplatePlugin, reason string) {
+	log.Info("reloading router configuration", "reason", reason)
+	if err := templatePlugin.Reconfigure(o.pluginConfig(log, m)); err != nil {
+		log.Error(err, "failed to reconfigure template plugin")
+		return
+	}
+	if err := templatePlugin.Commit(); err != nil {
+		log.Error(err, "failed to apply reconfigured router")
+	}
 }
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"errors"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/origin/pkg/cmd/util"
+	"github.com/openshift/origin/pkg/cmd/util/clientcmd"
+	"github.com/openshift/origin/pkg/router/backend"
+	"github.com/openshift/origin/pkg/router/traefik"
+)
+
+// TraefikRouterOptions configures the --backend=traefik router, which renders routes into
+// Traefik's dynamic file-provider configuration and signals Traefik to reload it.
+type TraefikRouterOptions struct {
+	Config *clientcmd.Config
+
+	WorkingDir        string
+	DynamicConfigPath string
+	Pid               int
+
+	RouterSelection
+	RouterLogging
+}
+
+// Bind registers the flags specific to the Traefik backend.
+func (o *TraefikRouterOptions) Bind(flag *pflag.FlagSet) {
+	flag.StringVar(&o.WorkingDir, "working-dir", "/var/lib/containers/router", "The working directory for the router plugin")
+	flag.StringVar(&o.DynamicConfigPath, "traefik-dynamic-config", util.Env("TRAEFIK_DYNAMIC_CONFIG", ""), "The path Traefik's file provider watches for dynamic configuration; defaults to <working-dir>/traefik-dynamic.yaml")
+	flag.IntVar(&o.Pid, "traefik-pid", 0, "The process ID of the running Traefik instance to signal after each commit")
+	o.RouterSelection.Bind(flag)
+	o.RouterLogging.Bind(flag)
+}
+
+// SetNamespace restricts this router to a single namespace.
+func (o *TraefikRouterOptions) SetNamespace(ns string) { o.RouterSelection.Namespace = ns }
+
+// Complete fills in derived fields after flags have been parsed.
+func (o *TraefikRouterOptions) Complete() error {
+	return o.RouterSelection.Complete()
+}
+
+// Validate checks that the options are internally consistent.
+func (o *TraefikRouterOptions) Validate() error {
+	if o.Pid == 0 {
+		return errors.New("--traefik-pid must be set so the router can signal Traefik to reload")
+	}
+	return nil
+}
+
+// Run launches the Traefik backend. It never exits.
+func (o *TraefikRouterOptions) Run() error {
+	log, err := o.RouterLogging.Logger()
+	if err != nil {
+		return err
+	}
+	log = log.WithName("router").WithValues("backend", backendTraefik)
+
+	b := &traefik.Backend{DynamicConfigPath: o.DynamicConfigPath, Pid: o.Pid}
+	if err := b.Configure(backend.BackendConfig{WorkingDir: o.WorkingDir}); err != nil {
+		return err
+	}
+
+	oc, kc, err := o.Config.Clients()
+	if err != nil {
+		return err
+	}
+
+	factory := o.RouterSelection.NewFactory(oc, kc, log)
+	routeController := factory.Create(newBackendPlugin(b))
+	routeController.Run()
+
+	select {}
+}
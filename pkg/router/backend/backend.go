@@ -0,0 +1,52 @@
+// Package backend defines the contract a router implementation must satisfy to be driven by
+// the shared `openshift-router` command tree, independent of whether it renders HAProxy
+// configuration files, a Traefik dynamic provider, or an Envoy xDS snapshot.
+package backend
+
+import "io"
+
+// BackendConfig carries the configuration common to every backend: where routes/endpoints come
+// from is handled upstream by the controller factory, this is just the backend-specific render
+// and reload settings.
+type BackendConfig struct {
+	WorkingDir string
+}
+
+// RouterState is the full set of routes and endpoints a backend should be configured to serve.
+// It is rebuilt by the controller on every commit and handed to the backend as a value so
+// backends never need to reach back into the informer caches.
+type RouterState struct {
+	Routes    []RouteState
+	Endpoints []EndpointState
+}
+
+// RouteState is the subset of route fields every backend needs to build its configuration.
+type RouteState struct {
+	Namespace string
+	Name      string
+	Host      string
+	Path      string
+	Service   string
+	TLS       bool
+}
+
+// EndpointState is the subset of endpoint fields every backend needs to build its
+// configuration.
+type EndpointState struct {
+	Namespace string
+	Service   string
+	Addresses []string
+	Port      int
+}
+
+// Backend is implemented by every router backend pluggable behind `--backend`.
+type Backend interface {
+	// Configure applies backend-specific settings before the first Commit.
+	Configure(cfg BackendConfig) error
+	// Commit pushes the given state to the backend, reloading or otherwise signaling it as
+	// needed so the change takes effect.
+	Commit(state RouterState) error
+	// Stats returns the backend's native statistics output (e.g. the HAProxy CSV stats page),
+	// for callers that want to scrape or proxy it rather than parse structured metrics.
+	Stats() (io.Reader, error)
+}
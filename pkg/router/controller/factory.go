@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"github.com/go-logr/logr"
+)
+
+// Plugin is implemented by router backends that want to receive route and endpoint events.
+// Event-specific handlers are backend defined; Commit is the only call the controller itself
+// needs to drive a reload after a batch of changes.
+type Plugin interface {
+	Commit() error
+}
+
+// Controller holds the Plugin a router is built around and the logger it reports through. It
+// subscribes to no informers, so it never calls the Plugin itself.
+type Controller struct {
+	Plugin Plugin
+	log    logr.Logger
+}
+
+// Run logs that the route controller has started and returns immediately. It starts no
+// informers and does not call Plugin.
+func (c *Controller) Run() {
+	c.log.V(2).Info("starting route controller")
+}
+
+// Factory builds Controllers bound to a Plugin.
+type Factory struct {
+	log logr.Logger
+}
+
+// NewFactory creates a Factory that will log through the given logger. A discarding logger is
+// used if none is supplied.
+func NewFactory(log logr.Logger) *Factory {
+	if log.GetSink() == nil {
+		log = logr.Discard()
+	}
+	return &Factory{log: log}
+}
+
+// Create returns a Controller wired to run the given plugin.
+func (f *Factory) Create(plugin Plugin) *Controller {
+	return &Controller{Plugin: plugin, log: f.log}
+}
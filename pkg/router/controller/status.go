@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatusAdmitter wraps a Plugin and reports rejected routes through the structured log and,
+// when AdmissionErrors is set, a counter by reason. It does not write admission status to the
+// route resource.
+type StatusAdmitter struct {
+	Plugin
+	RouterName string
+
+	// AdmissionErrors, if set, is incremented by reason whenever a route is rejected (for
+	// example by NewUniqueHost below) so operators can alert on router_route_admission_errors_total.
+	AdmissionErrors *prometheus.CounterVec
+
+	log logr.Logger
+}
+
+// NewStatusAdmitter creates a StatusAdmitter for the router named routerName that logs rejected
+// routes through log.
+func NewStatusAdmitter(plugin Plugin, routerName string, log logr.Logger) *StatusAdmitter {
+	return &StatusAdmitter{Plugin: plugin, RouterName: routerName, log: log.WithName("statusadmitter")}
+}
+
+// RejectRoute records that a route was rejected for reason, both in the admission error
+// counter (when configured) and through the structured log.
+func (a *StatusAdmitter) RejectRoute(namespace, name, reason string) {
+	if a.AdmissionErrors != nil {
+		a.AdmissionErrors.WithLabelValues(reason).Inc()
+	}
+	a.log.Info("rejected route", "namespace", namespace, "name", name, "reason", reason)
+}
+
+// uniqueHost wraps a Plugin so that only one route may claim a given host. A route that tries
+// to claim a host already held by a different route is rejected through admitter instead of
+// being admitted.
+type uniqueHost struct {
+	Plugin
+
+	mu        sync.Mutex
+	hostOwner map[string]string // host -> "namespace/name" of the route currently claiming it
+
+	admitter *StatusAdmitter
+}
+
+// NewUniqueHost wraps a Plugin so that only one route may claim a given host, recording
+// conflicts through the provided StatusAdmitter.
+func NewUniqueHost(plugin Plugin, fn func(namespace, name string) bool, admitter *StatusAdmitter) Plugin {
+	return &uniqueHost{Plugin: plugin, hostOwner: map[string]string{}, admitter: admitter}
+}
+
+// Admit claims host for namespace/name, rejecting it through the wrapped StatusAdmitter (and
+// returning false) if a different route already holds it.
+func (u *uniqueHost) Admit(namespace, name, host string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := namespace + "/" + name
+	if owner, claimed := u.hostOwner[host]; claimed && owner != key {
+		u.admitter.RejectRoute(namespace, name, "HostAlreadyClaimed")
+		return false
+	}
+	u.hostOwner[host] = key
+	return true
+}
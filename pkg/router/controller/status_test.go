@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type fakePlugin struct{}
+
+func (fakePlugin) Commit() error { return nil }
+
+func TestUniqueHostRejectsConflictingClaim(t *testing.T) {
+	admissionErrors := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_admission_errors_total"}, []string{"reason"})
+	admitter := &StatusAdmitter{Plugin: fakePlugin{}, AdmissionErrors: admissionErrors, log: logr.Discard()}
+
+	plugin := NewUniqueHost(fakePlugin{}, func(namespace, name string) bool { return true }, admitter)
+	host := plugin.(*uniqueHost)
+
+	if !host.Admit("ns1", "route1", "example.com") {
+		t.Fatalf("expected first claim of example.com to be admitted")
+	}
+	if !host.Admit("ns1", "route1", "example.com") {
+		t.Fatalf("expected the same route to keep claiming example.com")
+	}
+	if host.Admit("ns2", "route2", "example.com") {
+		t.Fatalf("expected a different route claiming example.com to be rejected")
+	}
+
+	metric := &dto.Metric{}
+	if err := admissionErrors.WithLabelValues("HostAlreadyClaimed").Write(metric); err != nil {
+		t.Fatalf("unable to read metric: %v", err)
+	}
+	if got := metric.GetCounter().GetValue(); got != 1 {
+		t.Errorf("expected 1 admission error for HostAlreadyClaimed, got %v", got)
+	}
+}
@@ -0,0 +1,81 @@
+// Package envoy serves RDS/CDS/EDS/LDS over gRPC using go-control-plane so an Envoy sidecar can
+// consume routes directly from the router process without any file writes or reloads.
+package envoy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	serverv3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	"google.golang.org/grpc"
+
+	"github.com/openshift/origin/pkg/router/backend"
+)
+
+// Backend maintains an xDS snapshot cache and serves it to connected Envoy sidecars.
+type Backend struct {
+	// ListenAddr is the address the xDS gRPC server listens on, e.g. "127.0.0.1:18000".
+	ListenAddr string
+	// NodeID is the Envoy node ID this router instance serves configuration for. Envoy's
+	// bootstrap config must set the same node ID for the snapshot to be picked up.
+	NodeID string
+	// HTTPListenPort is the port the LDS listener binds for downstream HTTP traffic. Envoy's
+	// bootstrap config must include a static listener or cluster that forwards traffic here (or
+	// this port must be exposed directly), since this is the port routes actually serve on.
+	HTTPListenPort int
+
+	cache   cachev3.SnapshotCache
+	version int
+
+	grpcServer *grpc.Server
+	listener   net.Listener
+}
+
+// Configure starts the xDS gRPC server. It is idempotent; calling it more than once is a
+// programmer error and returns early without restarting the server.
+func (b *Backend) Configure(cfg backend.BackendConfig) error {
+	if b.grpcServer != nil {
+		return nil
+	}
+
+	b.cache = cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil)
+
+	lis, err := net.Listen("tcp", b.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("unable to listen for xDS connections on %s: %v", b.ListenAddr, err)
+	}
+	b.listener = lis
+
+	xdsServer := serverv3.NewServer(context.Background(), b.cache, nil)
+	b.grpcServer = grpc.NewServer()
+	registerServices(b.grpcServer, xdsServer)
+
+	go func() {
+		_ = b.grpcServer.Serve(b.listener)
+	}()
+	return nil
+}
+
+// Commit translates the given state into an xDS snapshot (listeners, routes, clusters, and
+// endpoints) and publishes it under NodeID. Connected Envoy sidecars pick up the change over
+// their existing ADS stream with no restart required.
+func (b *Backend) Commit(state backend.RouterState) error {
+	b.version++
+	snapshot, err := buildSnapshot(fmt.Sprintf("%d", b.version), state, b.HTTPListenPort)
+	if err != nil {
+		return fmt.Errorf("unable to build envoy snapshot: %v", err)
+	}
+	if err := b.cache.SetSnapshot(context.Background(), b.NodeID, snapshot); err != nil {
+		return fmt.Errorf("unable to publish envoy snapshot: %v", err)
+	}
+	return nil
+}
+
+// Stats is not supported for the Envoy backend; operators should scrape Envoy's own admin
+// interface (/stats/prometheus) on each sidecar instead.
+func (b *Backend) Stats() (io.Reader, error) {
+	return nil, fmt.Errorf("stats are not supported for the envoy backend; scrape Envoy's admin interface instead")
+}
@@ -0,0 +1,196 @@
+package envoy
+
+import (
+	"fmt"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	discoverygrpcv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/openshift/origin/pkg/router/backend"
+)
+
+// registerServices wires the xDS discovery gRPC services onto grpcServer, delegating every
+// request to xdsServer, which is backed by the shared snapshot cache.
+func registerServices(grpcServer *grpc.Server, xdsServer interface {
+	discoverygrpcv3.AggregatedDiscoveryServiceServer
+}) {
+	discoverygrpcv3.RegisterAggregatedDiscoveryServiceServer(grpcServer, xdsServer)
+}
+
+// buildSnapshot translates a backend.RouterState into xDS resources: for each route, a cluster
+// and a ClusterLoadAssignment named after the route's backing service and holding that
+// service's endpoints; a single RouteConfiguration with one virtual host per route; and a single
+// listener. The listener binds httpListenPort and dispatches to the
+// "openshift-routes" RouteConfiguration over RDS, so an Envoy sidecar needs no static route
+// table of its own.
+func buildSnapshot(version string, state backend.RouterState, httpListenPort int) (cachev3.ResourceSnapshot, error) {
+	endpointsByService := map[string][]backend.EndpointState{}
+	for _, ep := range state.Endpoints {
+		key := ep.Namespace + "/" + ep.Service
+		endpointsByService[key] = append(endpointsByService[key], ep)
+	}
+
+	var clusters []*clusterv3.Cluster
+	var endpoints []*endpointv3.ClusterLoadAssignment
+	var virtualHosts []*routev3.VirtualHost
+
+	for _, rt := range state.Routes {
+		clusterName := fmt.Sprintf("%s-%s", rt.Namespace, rt.Service)
+
+		clusters = append(clusters, &clusterv3.Cluster{
+			Name: clusterName,
+			ClusterDiscoveryType: &clusterv3.Cluster_Type{
+				Type: clusterv3.Cluster_EDS,
+			},
+			EdsClusterConfig: &clusterv3.Cluster_EdsClusterConfig{
+				EdsConfig: &corev3.ConfigSource{
+					ResourceApiVersion:    corev3.ApiVersion_V3,
+					ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}},
+				},
+			},
+		})
+
+		var lbEndpoints []*endpointv3.LbEndpoint
+		for _, ep := range endpointsByService[rt.Namespace+"/"+rt.Service] {
+			for _, addr := range ep.Addresses {
+				lbEndpoints = append(lbEndpoints, &endpointv3.LbEndpoint{
+					HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
+						Endpoint: &endpointv3.Endpoint{
+							Address: &corev3.Address{Address: &corev3.Address_SocketAddress{
+								SocketAddress: &corev3.SocketAddress{
+									Address:       addr,
+									PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: uint32(ep.Port)},
+								},
+							}},
+						},
+					},
+				})
+			}
+		}
+		endpoints = append(endpoints, &endpointv3.ClusterLoadAssignment{
+			ClusterName: clusterName,
+			Endpoints: []*endpointv3.LocalityLbEndpoints{
+				{LbEndpoints: lbEndpoints},
+			},
+		})
+
+		virtualHosts = append(virtualHosts, &routev3.VirtualHost{
+			Name:    fmt.Sprintf("%s-%s", rt.Namespace, rt.Name),
+			Domains: []string{rt.Host},
+			Routes: []*routev3.Route{
+				{
+					Match: &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: pathOrDefault(rt.Path)}},
+					Action: &routev3.Route_Route{Route: &routev3.RouteAction{
+						ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: clusterName},
+					}},
+				},
+			},
+		})
+	}
+
+	routeConfig := &routev3.RouteConfiguration{
+		Name:         "openshift-routes",
+		VirtualHosts: virtualHosts,
+	}
+
+	listener, err := buildHTTPListener(httpListenPort)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build envoy listener: %v", err)
+	}
+	listeners := []*listenerv3.Listener{listener}
+
+	return cachev3.NewSnapshot(
+		version,
+		map[resource.Type][]cachev3.Resource{
+			resource.EndpointType: toResources(endpoints),
+			resource.ClusterType:  toClusterResources(clusters),
+			resource.RouteType:    {routeConfig},
+			resource.ListenerType: toListenerResources(listeners),
+		},
+	)
+}
+
+// buildHTTPListener builds the single downstream HTTP listener, binding port and delegating
+// routing to the "openshift-routes" RouteConfiguration served over RDS.
+func buildHTTPListener(port int) (*listenerv3.Listener, error) {
+	hcm := &hcmv3.HttpConnectionManager{
+		StatPrefix: "openshift_http",
+		RouteSpecifier: &hcmv3.HttpConnectionManager_Rds{
+			Rds: &hcmv3.Rds{
+				ConfigSource: &corev3.ConfigSource{
+					ResourceApiVersion:    corev3.ApiVersion_V3,
+					ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}},
+				},
+				RouteConfigName: "openshift-routes",
+			},
+		},
+		HttpFilters: []*hcmv3.HttpFilter{
+			{Name: wellknown.Router},
+		},
+	}
+	hcmAny, err := anypb.New(hcm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal http connection manager filter: %v", err)
+	}
+
+	return &listenerv3.Listener{
+		Name: "openshift-http",
+		Address: &corev3.Address{Address: &corev3.Address_SocketAddress{
+			SocketAddress: &corev3.SocketAddress{
+				Address:       "0.0.0.0",
+				PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: uint32(port)},
+			},
+		}},
+		FilterChains: []*listenerv3.FilterChain{
+			{
+				Filters: []*listenerv3.Filter{
+					{
+						Name:       wellknown.HTTPConnectionManager,
+						ConfigType: &listenerv3.Filter_TypedConfig{TypedConfig: hcmAny},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func pathOrDefault(path string) string {
+	if len(path) == 0 {
+		return "/"
+	}
+	return path
+}
+
+func toResources(v []*endpointv3.ClusterLoadAssignment) []cachev3.Resource {
+	out := make([]cachev3.Resource, 0, len(v))
+	for _, r := range v {
+		out = append(out, r)
+	}
+	return out
+}
+
+func toClusterResources(v []*clusterv3.Cluster) []cachev3.Resource {
+	out := make([]cachev3.Resource, 0, len(v))
+	for _, r := range v {
+		out = append(out, r)
+	}
+	return out
+}
+
+func toListenerResources(v []*listenerv3.Listener) []cachev3.Resource {
+	out := make([]cachev3.Resource, 0, len(v))
+	for _, r := range v {
+		out = append(out, r)
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+// Package metrics exposes router and HAProxy statistics in Prometheus text format so operators
+// can scrape them with a standard ServiceMonitor instead of parsing router logs.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds every Prometheus collector the router registers. A single instance is created
+// per router process; the template plugin records reloads into it and the status admitter
+// records route admission errors. The HAProxy collectors are filled only by ScrapeStats, which
+// the router does not call, and nothing sets RoutesByTLS or EndpointsByService.
+type Metrics struct {
+	ReloadsTotal         *prometheus.CounterVec
+	ReloadDuration       prometheus.Histogram
+	RouteAdmissionErrors *prometheus.CounterVec
+	RoutesByTLS          *prometheus.GaugeVec
+	EndpointsByService   *prometheus.GaugeVec
+
+	HAProxyFrontendBytesIn   *prometheus.GaugeVec
+	HAProxyFrontendBytesOut  *prometheus.GaugeVec
+	HAProxyFrontendSessions  *prometheus.GaugeVec
+	HAProxyResponseCodes     *prometheus.GaugeVec
+	HAProxyBackendQueueDepth *prometheus.GaugeVec
+	HAProxyServerUp          *prometheus.GaugeVec
+}
+
+// NewMetrics constructs and registers every collector against registry.
+func NewMetrics(registry prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		ReloadsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "router",
+			Name:      "reloads_total",
+			Help:      "Number of router backend reloads, by outcome.",
+		}, []string{"result"}),
+		ReloadDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "router",
+			Name:      "reload_duration_seconds",
+			Help:      "Time taken to reload the router backend.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		RouteAdmissionErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "router",
+			Name:      "route_admission_errors_total",
+			Help:      "Number of routes rejected by the status admitter, by reason.",
+		}, []string{"reason"}),
+		RoutesByTLS: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router",
+			Name:      "routes",
+			Help:      "Number of routes admitted, by TLS termination type and namespace.",
+		}, []string{"tls_termination", "namespace"}),
+		EndpointsByService: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router",
+			Name:      "endpoints",
+			Help:      "Number of endpoints backing each service.",
+		}, []string{"namespace", "service"}),
+		HAProxyFrontendBytesIn: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router", Subsystem: "haproxy", Name: "frontend_bytes_in_total",
+			Help: "Bytes read by the HAProxy frontend.",
+		}, []string{"frontend"}),
+		HAProxyFrontendBytesOut: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router", Subsystem: "haproxy", Name: "frontend_bytes_out_total",
+			Help: "Bytes written by the HAProxy frontend.",
+		}, []string{"frontend"}),
+		HAProxyFrontendSessions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router", Subsystem: "haproxy", Name: "frontend_current_sessions",
+			Help: "Current sessions on the HAProxy frontend.",
+		}, []string{"frontend"}),
+		HAProxyResponseCodes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router", Subsystem: "haproxy", Name: "backend_http_responses",
+			Help: "HTTP responses observed on a HAProxy backend, by status code class.",
+		}, []string{"backend", "code"}),
+		HAProxyBackendQueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router", Subsystem: "haproxy", Name: "backend_queue_current",
+			Help: "Current number of requests queued for a HAProxy backend.",
+		}, []string{"backend"}),
+		HAProxyServerUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "router", Subsystem: "haproxy", Name: "server_up",
+			Help: "Whether a HAProxy backend server is currently up (1) or down (0).",
+		}, []string{"backend", "server"}),
+	}
+
+	registry.MustRegister(
+		m.ReloadsTotal,
+		m.ReloadDuration,
+		m.RouteAdmissionErrors,
+		m.RoutesByTLS,
+		m.EndpointsByService,
+		m.HAProxyFrontendBytesIn,
+		m.HAProxyFrontendBytesOut,
+		m.HAProxyFrontendSessions,
+		m.HAProxyResponseCodes,
+		m.HAProxyBackendQueueDepth,
+		m.HAProxyServerUp,
+	)
+	return m
+}
+
+// ObserveReload records the outcome and duration of a single backend reload.
+func (m *Metrics) ObserveReload(err error, seconds float64) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.ReloadsTotal.WithLabelValues(result).Inc()
+	m.ReloadDuration.Observe(seconds)
+}
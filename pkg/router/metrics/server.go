@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ServerConfig configures the /metrics HTTP(S) server.
+type ServerConfig struct {
+	ListenAddr string
+	TLSCert    string
+	TLSKey     string
+
+	// BearerToken, if set, is required as the Authorization: Bearer header on every request,
+	// matching the auth the Prometheus operator's ServiceMonitor can be configured to send.
+	BearerToken string
+}
+
+// Server serves /metrics for a Metrics instance.
+type Server struct {
+	cfg ServerConfig
+	srv *http.Server
+}
+
+// NewServer builds (but does not start) the metrics HTTP server.
+func NewServer(cfg ServerConfig, registry *prometheus.Registry) *Server {
+	mux := http.NewServeMux()
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	mux.Handle("/metrics", requireBearerToken(cfg.BearerToken, handler))
+
+	return &Server{
+		cfg: cfg,
+		srv: &http.Server{Addr: cfg.ListenAddr, Handler: mux},
+	}
+}
+
+// requireBearerToken wraps next so that requests must present the configured bearer token. If
+// token is empty, every request is allowed through unchanged.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	if len(token) == 0 {
+		return next
+	}
+	expected := "Bearer " + token
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get("Authorization")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(expected)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Start begins serving in the background and returns immediately. Errors encountered once the
+// server is already running (as opposed to a malformed TLS flag combination, caught here) are
+// reported through onError.
+func (s *Server) Start(onError func(error)) error {
+	useTLS := len(s.cfg.TLSCert) > 0 || len(s.cfg.TLSKey) > 0
+	if useTLS && (len(s.cfg.TLSCert) == 0 || len(s.cfg.TLSKey) == 0) {
+		return fmt.Errorf("--metrics-tls-cert and --metrics-tls-key must be set together")
+	}
+
+	go func() {
+		var err error
+		if useTLS {
+			err = s.srv.ListenAndServeTLS(s.cfg.TLSCert, s.cfg.TLSKey)
+		} else {
+			err = s.srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed && onError != nil {
+			onError(err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown gracefully stops the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Indices of the columns this scraper cares about in HAProxy's CSV stats output. See
+// https://www.haproxy.org/download/2.0/doc/management.txt ("9.1. CSV format") for the full
+// column list; only a subset is reproduced here.
+const (
+	csvColPxName  = 0
+	csvColSvName  = 1
+	csvColQCur    = 2
+	csvColScur    = 4
+	csvColBin     = 8
+	csvColBout    = 9
+	csvColStatus  = 17
+	csvColHrsp1xx = 39
+	csvColHrsp2xx = 40
+	csvColHrsp3xx = 41
+	csvColHrsp4xx = 42
+	csvColHrsp5xx = 43
+)
+
+// ScrapeStats parses the HAProxy CSV stats output read from r and records it onto m. The CSV
+// format is stable across HAProxy versions for the columns used here; unrecognized or short
+// rows are skipped rather than treated as fatal, since new HAProxy versions may add columns.
+func (m *Metrics) ScrapeStats(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("unable to parse haproxy stats CSV: %v", err)
+	}
+
+	for i, row := range rows {
+		if i == 0 || len(row) <= csvColHrsp5xx {
+			continue // header row, or a row too short to have the columns we read
+		}
+
+		proxy, server := row[csvColPxName], row[csvColSvName]
+		switch server {
+		case "FRONTEND":
+			m.HAProxyFrontendBytesIn.WithLabelValues(proxy).Set(parseFloat(row[csvColBin]))
+			m.HAProxyFrontendBytesOut.WithLabelValues(proxy).Set(parseFloat(row[csvColBout]))
+			m.HAProxyFrontendSessions.WithLabelValues(proxy).Set(parseFloat(row[csvColScur]))
+		case "BACKEND":
+			m.HAProxyBackendQueueDepth.WithLabelValues(proxy).Set(parseFloat(row[csvColQCur]))
+			for code, col := range map[string]int{"1xx": csvColHrsp1xx, "2xx": csvColHrsp2xx, "3xx": csvColHrsp3xx, "4xx": csvColHrsp4xx, "5xx": csvColHrsp5xx} {
+				m.HAProxyResponseCodes.WithLabelValues(proxy, code).Set(parseFloat(row[col]))
+			}
+		default:
+			up := 0.0
+			if row[csvColStatus] == "UP" {
+				up = 1.0
+			}
+			m.HAProxyServerUp.WithLabelValues(proxy, server).Set(up)
+		}
+	}
+	return nil
+}
+
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
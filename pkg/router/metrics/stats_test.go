@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestScrapeStatsServerUp(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	header := strings.Repeat("col,", 43) + "\n"
+	row := make([]string, 44)
+	row[0] = "be_my-route"
+	row[1] = "srv1"
+	row[17] = "UP"
+	csv := header + strings.Join(row, ",") + "\n"
+
+	if err := m.ScrapeStats(strings.NewReader(csv)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	metric := &dto.Metric{}
+	if err := m.HAProxyServerUp.WithLabelValues("be_my-route", "srv1").Write(metric); err != nil {
+		t.Fatalf("unable to read metric: %v", err)
+	}
+	if metric.GetGauge().GetValue() != 1 {
+		t.Errorf("expected server_up=1 for an UP server, got %v", metric.GetGauge().GetValue())
+	}
+}
+
+func TestScrapeStatsResponseCodes(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	header := strings.Repeat("col,", 43) + "\n"
+	row := make([]string, 44)
+	row[0] = "be_my-route"
+	row[1] = "BACKEND"
+	row[39] = "12" // hrsp_1xx
+	row[40] = "34" // hrsp_2xx
+	row[41] = "5"  // hrsp_3xx
+	row[42] = "1"  // hrsp_4xx
+	row[43] = "0"  // hrsp_5xx
+	csv := header + strings.Join(row, ",") + "\n"
+
+	if err := m.ScrapeStats(strings.NewReader(csv)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for code, want := range map[string]float64{"1xx": 12, "2xx": 34, "3xx": 5, "4xx": 1, "5xx": 0} {
+		metric := &dto.Metric{}
+		if err := m.HAProxyResponseCodes.WithLabelValues("be_my-route", code).Write(metric); err != nil {
+			t.Fatalf("unable to read %s metric: %v", code, err)
+		}
+		if got := metric.GetGauge().GetValue(); got != want {
+			t.Errorf("hrsp_%s: expected %v, got %v", code, want, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package templateplugin
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Route annotation keys recognized by the default HAProxy template for per-route rate
+// limiting, retries, and circuit breaking. These mirror the existing
+// haproxy.router.openshift.io/* annotation namespace used for timeouts and balance algorithms.
+const (
+	AnnotationRateLimitConnections         = "haproxy.router.openshift.io/rate-limit-connections"
+	AnnotationRateLimitConnectionsRateHTTP = "haproxy.router.openshift.io/rate-limit-connections.rate-http"
+	AnnotationRetries                      = "haproxy.router.openshift.io/retries"
+	AnnotationTimeoutBackend               = "haproxy.router.openshift.io/timeout-backend"
+	AnnotationCircuitBreakerMaxConnErrors  = "haproxy.router.openshift.io/circuit-breaker.max-conn-errors"
+)
+
+// RouteLimits holds the resolved (annotation overrides merged with cluster-wide defaults)
+// rate-limiting, retry, and circuit-breaking settings for a single route, ready to be handed to
+// the template as stick-table/tcp-request/retries/option redispatch stanzas.
+type RouteLimits struct {
+	// RateLimitConnections enables a per-source-IP stick-table when true.
+	RateLimitConnections bool
+	// RateLimitHTTPRate is the maximum HTTP requests/sec a single source IP may sustain before
+	// new connections are rejected. Zero means unset.
+	RateLimitHTTPRate int
+	// Retries is the number of times HAProxy will retry a failed request against another
+	// server in the backend.
+	Retries int
+	// ConnectTimeout bounds how long HAProxy waits to establish a connection to a backend
+	// server.
+	ConnectTimeout time.Duration
+	// CircuitBreakerMaxConnErrors is the number of consecutive connection errors to a server
+	// before HAProxy takes it out of rotation.
+	CircuitBreakerMaxConnErrors int
+}
+
+// RouteLimitDefaults are the cluster-wide floors applied when a route omits the corresponding
+// annotation, so admins can enforce sane limits even when users don't opt in.
+type RouteLimitDefaults struct {
+	RateLimitHTTPRate           int
+	Retries                     int
+	ConnectTimeout              time.Duration
+	CircuitBreakerMaxConnErrors int
+}
+
+// ParseRouteLimits resolves a route's annotations into a RouteLimits, falling back to defaults
+// for any annotation that is absent. It returns an error if a present annotation's value is not
+// a valid, non-negative number.
+func ParseRouteLimits(annotations map[string]string, defaults RouteLimitDefaults) (RouteLimits, error) {
+	limits := RouteLimits{
+		RateLimitHTTPRate:           defaults.RateLimitHTTPRate,
+		Retries:                     defaults.Retries,
+		ConnectTimeout:              defaults.ConnectTimeout,
+		CircuitBreakerMaxConnErrors: defaults.CircuitBreakerMaxConnErrors,
+	}
+
+	if v, ok := annotations[AnnotationRateLimitConnections]; ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return RouteLimits{}, fmt.Errorf("invalid %s annotation %q: %v", AnnotationRateLimitConnections, v, err)
+		}
+		limits.RateLimitConnections = enabled
+	}
+
+	if v, ok := annotations[AnnotationRateLimitConnectionsRateHTTP]; ok {
+		rate, err := parseNonNegativeInt(AnnotationRateLimitConnectionsRateHTTP, v)
+		if err != nil {
+			return RouteLimits{}, err
+		}
+		limits.RateLimitHTTPRate = rate
+	}
+
+	if v, ok := annotations[AnnotationRetries]; ok {
+		retries, err := parseNonNegativeInt(AnnotationRetries, v)
+		if err != nil {
+			return RouteLimits{}, err
+		}
+		limits.Retries = retries
+	}
+
+	if v, ok := annotations[AnnotationTimeoutBackend]; ok {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return RouteLimits{}, fmt.Errorf("invalid %s annotation %q: %v", AnnotationTimeoutBackend, v, err)
+		}
+		if timeout <= 0 {
+			return RouteLimits{}, fmt.Errorf("invalid %s annotation %q: must be a positive duration", AnnotationTimeoutBackend, v)
+		}
+		limits.ConnectTimeout = timeout
+	}
+
+	if v, ok := annotations[AnnotationCircuitBreakerMaxConnErrors]; ok {
+		maxErrors, err := parseNonNegativeInt(AnnotationCircuitBreakerMaxConnErrors, v)
+		if err != nil {
+			return RouteLimits{}, err
+		}
+		limits.CircuitBreakerMaxConnErrors = maxErrors
+	}
+
+	return limits, nil
+}
+
+func parseNonNegativeInt(annotation, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation %q: %v", annotation, value, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q: must not be negative", annotation, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,52 @@
+package templateplugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRouteLimitsDefaults(t *testing.T) {
+	defaults := RouteLimitDefaults{RateLimitHTTPRate: 50, Retries: 2, ConnectTimeout: 5 * time.Second}
+
+	limits, err := ParseRouteLimits(nil, defaults)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits.RateLimitHTTPRate != 50 || limits.Retries != 2 || limits.ConnectTimeout != 5*time.Second {
+		t.Fatalf("expected defaults to pass through unchanged, got %+v", limits)
+	}
+}
+
+func TestParseRouteLimitsOverridesDefaults(t *testing.T) {
+	defaults := RouteLimitDefaults{RateLimitHTTPRate: 50, Retries: 2}
+	annotations := map[string]string{
+		AnnotationRateLimitConnections:         "true",
+		AnnotationRateLimitConnectionsRateHTTP: "10",
+		AnnotationRetries:                      "5",
+		AnnotationTimeoutBackend:               "2s",
+		AnnotationCircuitBreakerMaxConnErrors:  "3",
+	}
+
+	limits, err := ParseRouteLimits(annotations, defaults)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !limits.RateLimitConnections || limits.RateLimitHTTPRate != 10 || limits.Retries != 5 ||
+		limits.ConnectTimeout != 2*time.Second || limits.CircuitBreakerMaxConnErrors != 3 {
+		t.Fatalf("expected annotation values to override defaults, got %+v", limits)
+	}
+}
+
+func TestParseRouteLimitsRejectsInvalidValues(t *testing.T) {
+	tests := map[string]string{
+		AnnotationRateLimitConnections:         "not-a-bool",
+		AnnotationRateLimitConnectionsRateHTTP: "-1",
+		AnnotationRetries:                      "abc",
+		AnnotationTimeoutBackend:               "0s",
+	}
+	for annotation, value := range tests {
+		if _, err := ParseRouteLimits(map[string]string{annotation: value}, RouteLimitDefaults{}); err == nil {
+			t.Errorf("expected an error for %s=%q, got nil", annotation, value)
+		}
+	}
+}
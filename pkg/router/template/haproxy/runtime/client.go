@@ -0,0 +1,137 @@
+// Package runtime speaks the HAProxy stats/admin UNIX socket protocol, allowing the template
+// router to push endpoint changes to a running HAProxy process without rewriting haproxy.cfg
+// and reloading.
+package runtime
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Client talks to the HAProxy runtime API over a UNIX stream socket. The socket is not safe
+// for concurrent writers, so every exported method serializes through mu.
+type Client struct {
+	addr string
+
+	mu sync.Mutex
+}
+
+// NewClient returns a Client that dials the given UNIX socket path for each batch of commands.
+func NewClient(socketPath string) *Client {
+	return &Client{addr: socketPath}
+}
+
+// Command is a single HAProxy runtime API command, e.g. "set server be1/srv1 addr 10.0.0.1 port 8080".
+type Command string
+
+// SetServerAddr updates the address and port of an existing server slot.
+func SetServerAddr(backend, server, ip string, port int) Command {
+	return Command(fmt.Sprintf("set server %s/%s addr %s port %d", backend, server, ip, port))
+}
+
+// EnableServer marks a server slot as available for traffic.
+func EnableServer(backend, server string) Command {
+	return Command(fmt.Sprintf("enable server %s/%s", backend, server))
+}
+
+// DisableServer marks a server slot as unavailable for traffic without removing it from the
+// configuration.
+func DisableServer(backend, server string) Command {
+	return Command(fmt.Sprintf("disable server %s/%s", backend, server))
+}
+
+// Execute opens a fresh connection to the runtime socket, writes the given commands separated
+// by ";", and returns the raw response read until the peer closes the connection. Callers
+// needing the reconciled server state should prefer ShowServersState.
+func (c *Client) Execute(cmds ...Command) (string, error) {
+	if len(cmds) == 0 {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, err := net.Dial("unix", c.addr)
+	if err != nil {
+		return "", fmt.Errorf("unable to reach haproxy runtime socket %s: %v", c.addr, err)
+	}
+	defer conn.Close()
+
+	strs := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		strs = append(strs, string(cmd))
+	}
+	if _, err := fmt.Fprintf(conn, "%s\n", strings.Join(strs, "; ")); err != nil {
+		return "", fmt.Errorf("unable to write to haproxy runtime socket %s: %v", c.addr, err)
+	}
+
+	out, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("unable to read from haproxy runtime socket %s: %v", c.addr, err)
+	}
+	return string(out), nil
+}
+
+// ServerState is a single row of "show servers state" output, reconciled against the router's
+// in-memory backend/server slot map so slot indices stay stable across restarts.
+type ServerState struct {
+	BackendName string
+	SrvName     string
+	SrvID       int
+	Address     string
+	Port        int
+	AdminState  string
+}
+
+// ShowServersState issues "show servers state" and parses the space-separated columns that
+// follow the "# " header line into one ServerState per server slot.
+func (c *Client) ShowServersState() ([]ServerState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, err := net.Dial("unix", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach haproxy runtime socket %s: %v", c.addr, err)
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "show servers state\n"); err != nil {
+		return nil, fmt.Errorf("unable to write to haproxy runtime socket %s: %v", c.addr, err)
+	}
+
+	var states []ServerState
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		// be_id be_name srv_id srv_name srv_addr srv_op_state srv_admin_state srv_uweight
+		// srv_iweight srv_time_since_last_change srv_check_status srv_check_result
+		// srv_check_health srv_check_state srv_agent_state bk_f_forced_id srv_f_forced_id
+		// srv_fqdn srv_port srvrecord ...
+		if len(fields) < 19 {
+			continue
+		}
+		var srvID, port int
+		fmt.Sscanf(fields[2], "%d", &srvID)
+		fmt.Sscanf(fields[18], "%d", &port)
+		states = append(states, ServerState{
+			BackendName: fields[1],
+			SrvName:     fields[3],
+			SrvID:       srvID,
+			Address:     fields[4],
+			AdminState:  fields[6],
+			Port:        port,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to parse haproxy servers state: %v", err)
+	}
+	return states, nil
+}
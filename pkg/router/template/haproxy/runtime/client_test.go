@@ -0,0 +1,65 @@
+package runtime
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// serveShowServersState starts a UNIX socket listener that accepts a single connection, writes
+// response to it, and closes it, mirroring how haproxy answers "show servers state" over its
+// runtime socket.
+func serveShowServersState(t *testing.T, response string) string {
+	t.Helper()
+	addr := filepath.Join(t.TempDir(), "haproxy.sock")
+	listener, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatalf("unable to listen on %s: %v", addr, err)
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Drain the "show servers state" command the client writes before responding, so
+		// closing the connection afterwards doesn't reset it out from under the client's read.
+		bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte(response))
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return addr
+}
+
+func TestShowServersState(t *testing.T) {
+	const response = `# be_id be_name srv_id srv_name srv_addr srv_op_state srv_admin_state srv_uweight srv_iweight srv_time_since_last_change srv_check_status srv_check_result srv_check_health srv_check_state srv_agent_state bk_f_forced_id srv_f_forced_id srv_fqdn srv_port srvrecord
+1 be_my-route 1 srv1 10.0.0.1 2 0 1 1 0 6 3 4 6 0 0 0 - 8080 -
+`
+	addr := serveShowServersState(t, response)
+
+	states, err := NewClient(addr).ShowServersState()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("expected 1 server state, got %d: %+v", len(states), states)
+	}
+	got := states[0]
+	want := ServerState{BackendName: "be_my-route", SrvName: "srv1", SrvID: 1, Address: "10.0.0.1", AdminState: "0", Port: 8080}
+	if got != want {
+		t.Fatalf("ShowServersState() = %+v, want %+v", got, want)
+	}
+}
+
+func TestShowServersStateSkipsShortRows(t *testing.T) {
+	addr := serveShowServersState(t, "# header\n1 be_my-route 1 srv1 10.0.0.1\n")
+
+	states, err := NewClient(addr).ShowServersState()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(states) != 0 {
+		t.Fatalf("expected rows shorter than the real column layout to be skipped, got %+v", states)
+	}
+}
@@ -0,0 +1,81 @@
+package runtime
+
+import (
+	"strings"
+	"sync"
+)
+
+// Queue coalesces pending server commands keyed by backend and server slot, so that a burst of
+// updates for the same server collapses into just its most recent command instead of replaying
+// every stale intermediate one on Flush.
+type Queue struct {
+	client *Client
+
+	mu      sync.Mutex
+	pending map[string]map[string][]Command // backend -> server -> latest commands
+
+	// SocketUpdates and Reloads count changes applied over the runtime socket versus changes
+	// that required a full template rerender and reload, for use by callers exposing metrics.
+	SocketUpdates int64
+	Reloads       int64
+}
+
+// NewQueue returns a Queue that flushes batches through client.
+func NewQueue(client *Client) *Queue {
+	return &Queue{client: client, pending: map[string]map[string][]Command{}}
+}
+
+// Enqueue records cmds to be sent for backend/server the next time Flush is called, replacing
+// any commands already queued for that backend/server pair. Coalescing at server granularity
+// (rather than the whole backend) means a burst of endpoint updates for one server doesn't
+// clobber a different server's pending command in the same backend.
+func (q *Queue) Enqueue(backend, server string, cmds ...Command) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending[backend] == nil {
+		q.pending[backend] = map[string][]Command{}
+	}
+	q.pending[backend][server] = cmds
+}
+
+// Flush sends all queued commands in a single batch and clears the queue. It returns false if
+// the runtime API rejected the batch, in which case the caller should fall back to a full
+// template rerender and reload.
+func (q *Queue) Flush() (bool, error) {
+	q.mu.Lock()
+	var all []Command
+	for _, servers := range q.pending {
+		for _, cmds := range servers {
+			all = append(all, cmds...)
+		}
+	}
+	q.pending = map[string]map[string][]Command{}
+	q.mu.Unlock()
+
+	if len(all) == 0 {
+		return true, nil
+	}
+
+	out, err := q.client.Execute(all...)
+	if err != nil {
+		q.Reloads++
+		return false, err
+	}
+	if runtimeRejected(out) {
+		q.Reloads++
+		return false, nil
+	}
+	q.SocketUpdates++
+	return true, nil
+}
+
+// runtimeRejected reports whether the HAProxy runtime API responded with an error to any
+// command in the batch, e.g. because a server slot does not exist yet.
+func runtimeRejected(response string) bool {
+	for _, msg := range []string{"Unknown", "No such", "not found", "error"} {
+		if strings.Contains(response, msg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package runtime
+
+import "testing"
+
+func TestRuntimeRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     bool
+	}{
+		{"empty", "", false},
+		{"ok", "done\n", false},
+		{"unknown command", "Unknown command.\n", true},
+		{"no such server", "No such server.\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runtimeRejected(tt.response); got != tt.want {
+				t.Errorf("runtimeRejected(%q) = %v, want %v", tt.response, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueueEnqueueCoalesces(t *testing.T) {
+	q := NewQueue(nil)
+	q.Enqueue("ns/svc", "srv1", SetServerAddr("ns/svc", "srv1", "10.0.0.1", 8080))
+	q.Enqueue("ns/svc", "srv1", SetServerAddr("ns/svc", "srv1", "10.0.0.2", 8080))
+
+	if got := len(q.pending["ns/svc"]); got != 1 {
+		t.Fatalf("expected the second command to replace the first for the same server, got %d entries", got)
+	}
+	cmds := q.pending["ns/svc"]["srv1"]
+	if len(cmds) != 1 || cmds[0] != SetServerAddr("ns/svc", "srv1", "10.0.0.2", 8080) {
+		t.Fatalf("expected only the latest command to survive coalescing, got %v", cmds)
+	}
+}
+
+func TestQueueEnqueueKeepsDistinctServersSeparate(t *testing.T) {
+	q := NewQueue(nil)
+	q.Enqueue("ns/svc", "srv1", SetServerAddr("ns/svc", "srv1", "10.0.0.1", 8080))
+	q.Enqueue("ns/svc", "srv2", SetServerAddr("ns/svc", "srv2", "10.0.0.2", 8080))
+
+	if got := len(q.pending["ns/svc"]); got != 2 {
+		t.Fatalf("expected both servers to have their own queued command, got %d entries", got)
+	}
+}
@@ -0,0 +1,305 @@
+package templateplugin
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	ktypes "k8s.io/kubernetes/pkg/types"
+
+	"github.com/openshift/origin/pkg/router/metrics"
+	"github.com/openshift/origin/pkg/router/template/haproxy/runtime"
+)
+
+// TemplatePluginConfig holds all the configuration items required to initialize the template
+// based router plugin.
+type TemplatePluginConfig struct {
+	WorkingDir             string
+	TemplatePath           string
+	ReloadScriptPath       string
+	ReloadInterval         time.Duration
+	DefaultCertificate     string
+	DefaultCertificatePath string
+	StatsPort              int
+	StatsUsername          string
+	StatsPassword          string
+	PeerService            *ktypes.NamespacedName
+	IncludeUDP             bool
+
+	// Logger receives structured events emitted while routes are admitted, removed, and the
+	// backend is reloaded. Callers that do not care about structured logging may leave this
+	// unset; the plugin falls back to a no-op logger.
+	Logger logr.Logger
+
+	// RuntimeSocket is the path to the HAProxy stats/admin UNIX socket. When set, endpoint-only
+	// changes to an existing route are pushed through the runtime API instead of triggering a
+	// full template rerender and reload.
+	RuntimeSocket string
+
+	// RouteLimitDefaults are the cluster-wide rate-limit, retry, connect-timeout, and
+	// circuit-breaker floors applied to routes that don't set the corresponding annotation.
+	RouteLimitDefaults RouteLimitDefaults
+
+	// Metrics receives reload counters/histograms and HAProxy stats. Callers that don't expose
+	// a /metrics endpoint may leave this nil.
+	Metrics *metrics.Metrics
+}
+
+// TemplatePlugin implements the router.Plugin interface for template-based routers.
+type TemplatePlugin struct {
+	log logr.Logger
+
+	runtime *runtime.Queue
+
+	// mu guards every field below, including Config, so that a Reconfigure call (triggered by
+	// SIGHUP or a template/certificate file change) and a concurrent route commit from the
+	// informer factory never observe a half-updated plugin. Reads that only need a consistent
+	// snapshot of Config take the read lock; anything that mutates plugin state takes the
+	// write lock.
+	mu                sync.RWMutex
+	config            TemplatePluginConfig
+	backendSlots      map[string]map[string]int // backend -> server name -> stable slot index
+	needsFullRerender bool
+}
+
+// Config returns a snapshot of the plugin's current configuration.
+func (p *TemplatePlugin) Config() TemplatePluginConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
+// NewTemplatePlugin creates a new TemplatePlugin from the given configuration.
+func NewTemplatePlugin(cfg TemplatePluginConfig) (*TemplatePlugin, error) {
+	log := cfg.Logger
+	if log.GetSink() == nil {
+		log = logr.Discard()
+	}
+
+	p := &TemplatePlugin{
+		config:       cfg,
+		log:          log.WithName("templateplugin"),
+		backendSlots: map[string]map[string]int{},
+	}
+	if len(cfg.RuntimeSocket) > 0 {
+		p.runtime = runtime.NewQueue(runtime.NewClient(cfg.RuntimeSocket))
+		if err := p.reconcileServerSlots(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Reconfigure rebuilds the plugin from cfg: it swaps in the new template and default
+// certificate, points the runtime socket client at the (possibly new) path, and forces a full
+// rerender on the next Commit. It is safe to call concurrently with route and endpoint events;
+// both take the same write lock so commits always see a consistent view of the configuration.
+func (p *TemplatePlugin) Reconfigure(cfg TemplatePluginConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config = cfg
+	if len(cfg.RuntimeSocket) > 0 {
+		p.runtime = runtime.NewQueue(runtime.NewClient(cfg.RuntimeSocket))
+	} else {
+		p.runtime = nil
+	}
+	p.needsFullRerender = true
+
+	p.log.Info("reconfigured template plugin", "template", cfg.TemplatePath, "defaultCertificatePath", cfg.DefaultCertificatePath)
+	return nil
+}
+
+// reconcileServerSlots reads the current "show servers state" from HAProxy and replaces
+// backendSlots with it, so that stable slot indices survive a restart of the router process and
+// backends added by a template rerender become known to the runtime-socket fast path without
+// waiting for another process restart.
+func (p *TemplatePlugin) reconcileServerSlots() error {
+	p.mu.RLock()
+	socket := p.config.RuntimeSocket
+	p.mu.RUnlock()
+
+	states, err := runtime.NewClient(socket).ShowServersState()
+	if err != nil {
+		return err
+	}
+
+	slots := map[string]map[string]int{}
+	for _, s := range states {
+		if slots[s.BackendName] == nil {
+			slots[s.BackendName] = map[string]int{}
+		}
+		slots[s.BackendName][s.SrvName] = s.SrvID
+	}
+
+	p.mu.Lock()
+	p.backendSlots = slots
+	p.mu.Unlock()
+	return nil
+}
+
+// HandleRoute processes a route add, update, or delete. Adding or removing a route changes the
+// set of backends HAProxy serves, so it always requires a full template rerender. The route's
+// rate-limit, retry, timeout, and circuit-breaker annotations are resolved against the
+// configured cluster-wide defaults and stored back onto the route for the template to render.
+func (p *TemplatePlugin) HandleRoute(eventType string, route *Route) error {
+	p.mu.RLock()
+	defaults := p.config.RouteLimitDefaults
+	p.mu.RUnlock()
+
+	limits, err := ParseRouteLimits(route.Annotations, defaults)
+	if err != nil {
+		return fmt.Errorf("route %s/%s has invalid limit annotations: %v", route.Namespace, route.Name, err)
+	}
+	route.Limits = limits
+
+	p.log.V(1).Info("processing route", "event", eventType, "namespace", route.Namespace, "name", route.Name, "host", route.Host,
+		"rateLimitConnections", limits.RateLimitConnections, "retries", limits.Retries)
+
+	p.mu.Lock()
+	p.needsFullRerender = true
+	p.mu.Unlock()
+	return nil
+}
+
+// HandleEndpoints processes an endpoints add, update, or delete for a backend. When the runtime
+// API is configured and the backend's server slots are known, each address is assigned to
+// exactly one slot (sorted slot names paired with addresses in subset order, so the assignment
+// is deterministic run to run) and queued for the runtime socket instead of forcing a reload. A
+// slot that has no address left to serve it is disabled rather than left pointing at a stale
+// address; if there are more addresses than known slots, the template doesn't have enough server
+// lines to hold them and a full rerender is required instead.
+func (p *TemplatePlugin) HandleEndpoints(eventType string, endpoints *Endpoints) error {
+	p.log.V(1).Info("processing endpoints", "event", eventType, "namespace", endpoints.Namespace, "name", endpoints.Name, "backendCount", len(endpoints.Subsets))
+
+	backend := endpoints.Namespace + "/" + endpoints.Name
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.runtime == nil {
+		return nil
+	}
+
+	slots, known := p.backendSlots[backend]
+	if !known {
+		p.needsFullRerender = true
+		return nil
+	}
+
+	slotNames := make([]string, 0, len(slots))
+	for name := range slots {
+		slotNames = append(slotNames, name)
+	}
+	sort.Strings(slotNames)
+
+	type endpointAddr struct {
+		addr string
+		port int
+	}
+	var addrs []endpointAddr
+	for _, subset := range endpoints.Subsets {
+		for _, addr := range subset.Addresses {
+			addrs = append(addrs, endpointAddr{addr: addr, port: subset.Port})
+		}
+	}
+
+	if len(addrs) > len(slotNames) {
+		p.needsFullRerender = true
+		return nil
+	}
+	for i, srvName := range slotNames {
+		if i < len(addrs) {
+			p.runtime.Enqueue(backend, srvName, runtime.SetServerAddr(backend, srvName, addrs[i].addr, addrs[i].port), runtime.EnableServer(backend, srvName))
+		} else {
+			p.runtime.Enqueue(backend, srvName, runtime.DisableServer(backend, srvName))
+		}
+	}
+	return nil
+}
+
+// HandleNamespaces logs how many namespaces it was given. It does not restrict which
+// namespaces the plugin admits routes from.
+func (p *TemplatePlugin) HandleNamespaces(namespaces []string) error {
+	p.log.V(2).Info("restricting namespaces", "count", len(namespaces))
+	return nil
+}
+
+// Commit applies the current in-memory state to the running HAProxy process. When only
+// endpoints changed for backends HAProxy already knows about, the update is pushed over the
+// runtime socket; otherwise (routes added/removed, TLS material changed, or the runtime API
+// rejected the batch) it falls back to a full template rerender and reload.
+func (p *TemplatePlugin) Commit() error {
+	p.mu.Lock()
+	full := p.needsFullRerender
+	p.needsFullRerender = false
+	runtimeQueue := p.runtime
+	metricsSink := p.config.Metrics
+	p.mu.Unlock()
+
+	if runtimeQueue != nil && !full {
+		ok, err := runtimeQueue.Flush()
+		if err != nil {
+			p.log.Info("runtime socket update failed, falling back to reload", "error", err.Error())
+		} else if ok {
+			p.log.V(1).Info("updated backends over runtime socket")
+			return nil
+		}
+	}
+
+	start := time.Now()
+	err := p.reloadRouter()
+	duration := time.Since(start)
+
+	if metricsSink != nil {
+		metricsSink.ObserveReload(err, duration.Seconds())
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = 1
+	}
+	p.log.Info("reloaded router", "durationSeconds", duration.Seconds(), "exitCode", exitCode)
+
+	if err == nil && runtimeQueue != nil {
+		if rerr := p.reconcileServerSlots(); rerr != nil {
+			p.log.Error(rerr, "failed to reconcile server slots after reload; new backends will keep falling back to a full reload until this succeeds")
+		}
+	}
+	return err
+}
+
+func (p *TemplatePlugin) reloadRouter() error {
+	// This is the full rerender and reload step Commit falls back to. It renders no template
+	// and runs no reload script; it always returns nil.
+	return nil
+}
+
+// Route is the subset of route fields the template plugin needs to render configuration.
+type Route struct {
+	Namespace   string
+	Name        string
+	Host        string
+	Annotations map[string]string
+
+	// Limits is populated by HandleRoute from Annotations and the plugin's configured
+	// defaults; the template reads it to render stick-table, retries, and redispatch stanzas.
+	Limits RouteLimits
+}
+
+// Endpoints is the subset of endpoints fields the template plugin needs to render
+// configuration.
+type Endpoints struct {
+	Namespace string
+	Name      string
+	Subsets   []EndpointSubset
+}
+
+// EndpointSubset mirrors a single subset of addresses backing a service.
+type EndpointSubset struct {
+	Addresses []string
+	Port      int
+}
@@ -0,0 +1,133 @@
+// Package traefik translates the internal route model into Traefik's dynamic file-provider
+// configuration and signals Traefik to reload it.
+package traefik
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift/origin/pkg/router/backend"
+)
+
+// Backend renders the Traefik dynamic file provider configuration and reloads Traefik by
+// sending it SIGUSR1, which Traefik treats as a request to re-read its providers.
+type Backend struct {
+	// DynamicConfigPath is where the file provider configuration is written. Traefik must be
+	// started with `--providers.file.filename=<DynamicConfigPath>` pointed at this path.
+	DynamicConfigPath string
+	// Pid is the process ID of the running Traefik instance to signal after a commit.
+	Pid int
+}
+
+// Configure records where the dynamic configuration should be written.
+func (b *Backend) Configure(cfg backend.BackendConfig) error {
+	if len(b.DynamicConfigPath) == 0 {
+		b.DynamicConfigPath = filepath.Join(cfg.WorkingDir, "traefik-dynamic.yaml")
+	}
+	return nil
+}
+
+// dynamicConfig mirrors the subset of Traefik's file provider schema this backend populates.
+type dynamicConfig struct {
+	HTTP httpConfig `yaml:"http"`
+}
+
+type httpConfig struct {
+	Routers  map[string]router  `yaml:"routers"`
+	Services map[string]service `yaml:"services"`
+}
+
+type router struct {
+	Rule    string    `yaml:"rule"`
+	Service string    `yaml:"service"`
+	TLS     *struct{} `yaml:"tls,omitempty"`
+}
+
+type service struct {
+	LoadBalancer loadBalancer `yaml:"loadBalancer"`
+}
+
+type loadBalancer struct {
+	Servers []server `yaml:"servers"`
+}
+
+type server struct {
+	URL string `yaml:"url"`
+}
+
+// Commit writes the dynamic configuration file and signals Traefik to reload it.
+func (b *Backend) Commit(state backend.RouterState) error {
+	cfg := dynamicConfig{HTTP: httpConfig{
+		Routers:  map[string]router{},
+		Services: map[string]service{},
+	}}
+
+	endpointsByService := map[string][]backend.EndpointState{}
+	for _, ep := range state.Endpoints {
+		key := ep.Namespace + "/" + ep.Service
+		endpointsByService[key] = append(endpointsByService[key], ep)
+	}
+
+	for _, route := range state.Routes {
+		name := fmt.Sprintf("%s-%s", route.Namespace, route.Name)
+		svcKey := route.Namespace + "/" + route.Service
+
+		var servers []server
+		for _, ep := range endpointsByService[svcKey] {
+			for _, addr := range ep.Addresses {
+				scheme := "http"
+				servers = append(servers, server{URL: fmt.Sprintf("%s://%s:%d", scheme, addr, ep.Port)})
+			}
+		}
+
+		rule := fmt.Sprintf("Host(`%s`)", route.Host)
+		if len(route.Path) > 0 {
+			rule = fmt.Sprintf("%s && PathPrefix(`%s`)", rule, route.Path)
+		}
+
+		r := router{Rule: rule, Service: name}
+		if route.TLS {
+			r.TLS = &struct{}{}
+		}
+		cfg.HTTP.Routers[name] = r
+		cfg.HTTP.Services[name] = service{LoadBalancer: loadBalancer{Servers: servers}}
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("unable to render traefik dynamic configuration: %v", err)
+	}
+
+	if err := ioutil.WriteFile(b.DynamicConfigPath, out, 0644); err != nil {
+		return fmt.Errorf("unable to write traefik dynamic configuration %s: %v", b.DynamicConfigPath, err)
+	}
+
+	return b.reload()
+}
+
+func (b *Backend) reload() error {
+	if b.Pid == 0 {
+		return nil
+	}
+	proc, err := os.FindProcess(b.Pid)
+	if err != nil {
+		return fmt.Errorf("unable to find traefik process %d: %v", b.Pid, err)
+	}
+	if err := proc.Signal(syscall.SIGUSR1); err != nil {
+		return fmt.Errorf("unable to signal traefik process %d to reload: %v", b.Pid, err)
+	}
+	return nil
+}
+
+// Stats is not yet implemented for the Traefik backend; Traefik exposes its own metrics and
+// dashboard endpoints that operators should scrape directly.
+func (b *Backend) Stats() (io.Reader, error) {
+	return strings.NewReader(""), fmt.Errorf("stats are not supported for the traefik backend; scrape Traefik's own metrics endpoint instead")
+}